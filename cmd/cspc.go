@@ -0,0 +1,88 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/generate"
+	"github.com/openebs/openebsctl/pkg/util"
+)
+
+var (
+	cspcGenNodes    []string
+	cspcGenDevices  int
+	cspcGenPoolType string
+	cspcGenSize     string
+	cspcGenWithPDB  bool
+)
+
+// NewCmdGenerateCSPC generates a CStorPoolCluster YAML from a set of nodes
+// and block devices, e.g.:
+//
+//	openebsctl cspc generate --nodes node1,node2,node3 --devices 2 --size 100Gi --type mirror
+func NewCmdGenerateCSPC() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a CStorPoolCluster YAML from a set of nodes and block devices",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(runCSPCGenerate(), util.Fatal)
+		},
+	}
+	cmd.Flags().StringSliceVar(&cspcGenNodes, "nodes", []string{}, "comma separated list of node names to generate pools on")
+	cmd.Flags().IntVar(&cspcGenDevices, "devices", 1, "number of block devices per pool")
+	cmd.Flags().StringVar(&cspcGenPoolType, "type", "stripe", "pool type: stripe, mirror, raidz, raidz2")
+	cmd.Flags().StringVar(&cspcGenSize, "size", "", "target usable capacity per pool, e.g. 100Gi; leave empty to pick any devices BDs")
+	cmd.Flags().BoolVar(&cspcGenWithPDB, "pdb", false, "also emit a PodDisruptionBudget protecting the generated pools")
+	return cmd
+}
+
+// runCSPCGenerate resolves the k8s client and --size flag and delegates to
+// generate.Cspc, printing the resulting YAML.
+func runCSPCGenerate() error {
+	c, err := client.NewK8sClient("")
+	if err != nil {
+		return err
+	}
+	sizeGi, err := sizeFlagToGiB(cspcGenSize)
+	if err != nil {
+		return err
+	}
+	out, err := generate.Cspc(c, cspcGenNodes, cspcGenDevices, cspcGenPoolType, sizeGi, cspcGenWithPDB)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+// sizeFlagToGiB parses a Kubernetes quantity string, such as "100Gi", into
+// whole GiB. An empty size means no capacity target is requested.
+func sizeFlagToGiB(size string) (int, error) {
+	if size == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(size)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --size %q: %v", size, err)
+	}
+	return int(q.Value() / (1024 * 1024 * 1024)), nil
+}
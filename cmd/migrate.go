@@ -0,0 +1,84 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/generate/migrate"
+	"github.com/openebs/openebsctl/pkg/util"
+)
+
+var (
+	migrateFrom      string
+	migrateTo        string
+	migrateNamespace string
+	migratePVC       string
+	migrateApply     bool
+)
+
+// NewCmdMigrate groups the `openebsctl migrate` verbs for moving a PVC
+// between OpenEBS storage engines.
+func NewCmdMigrate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move a PVC from one OpenEBS storage engine to another",
+	}
+	cmd.AddCommand(NewCmdMigratePlan())
+	return cmd
+}
+
+// NewCmdMigratePlan generates (and optionally applies) the manifests needed
+// to move a PVC between engines, e.g.:
+//
+//	openebsctl migrate plan --from jiva --to cstor --namespace default --pvc mysql-data
+func NewCmdMigratePlan() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Plan a migration of a PVC to a different storage engine",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(runMigratePlan(), util.Fatal)
+		},
+	}
+	cmd.Flags().StringVar(&migrateFrom, "from", "", "source storage engine: jiva, cstor or mayastor (required)")
+	cmd.Flags().StringVar(&migrateTo, "to", "", "destination storage engine: jiva, cstor or mayastor (required)")
+	cmd.Flags().StringVarP(&migrateNamespace, "namespace", "n", "", "namespace of the source PVC (required)")
+	cmd.Flags().StringVar(&migratePVC, "pvc", "", "name of the source PVC (required)")
+	cmd.Flags().BoolVar(&migrateApply, "apply", false, "create the destination PVC in the cluster instead of printing the manifests")
+	return cmd
+}
+
+// runMigratePlan resolves the k8s client and delegates to migrate.Plan.Run,
+// which itself prints the dry-run summary and, unless --apply is set, the
+// generated manifests.
+func runMigratePlan() error {
+	c, err := client.NewK8sClient("")
+	if err != nil {
+		return err
+	}
+	p := &migrate.Plan{
+		C:         c,
+		From:      migrateFrom,
+		To:        migrateTo,
+		Namespace: migrateNamespace,
+		PVC:       migratePVC,
+		Apply:     migrateApply,
+	}
+	_, err = p.Run()
+	return err
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/snapshot"
+	"github.com/openebs/openebsctl/pkg/util"
+)
+
+var (
+	snapNamespace string
+	snapSourcePVC string
+	snapClassName string
+)
+
+// NewCmdSnapshot groups the `openebsctl snapshot` verbs for VolumeSnapshots
+// of cstor/jiva-backed PVs.
+func NewCmdSnapshot() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Get, describe, create or delete VolumeSnapshots of cstor/jiva volumes",
+	}
+	cmd.PersistentFlags().StringVarP(&snapNamespace, "namespace", "n", "", "namespace of the source PVC/VolumeSnapshot")
+	cmd.AddCommand(NewCmdSnapshotGet())
+	cmd.AddCommand(NewCmdSnapshotDescribe())
+	cmd.AddCommand(NewCmdSnapshotCreate())
+	cmd.AddCommand(NewCmdSnapshotDelete())
+	return cmd
+}
+
+// NewCmdSnapshotGet lists VolumeSnapshots, e.g.:
+//
+//	openebsctl snapshot get --pvc mysql-data
+func NewCmdSnapshotGet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "List VolumeSnapshots of cstor/jiva volumes",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(runSnapshotGet(), util.Fatal)
+		},
+	}
+	cmd.Flags().StringVar(&snapSourcePVC, "pvc", "", "only show snapshots sourced from this PVC")
+	return cmd
+}
+
+func runSnapshotGet() error {
+	c, err := client.NewK8sClient("")
+	if err != nil {
+		return err
+	}
+	rows, err := snapshot.GetSnapshots(c, snapNamespace, snapSourcePVC)
+	if err != nil {
+		return err
+	}
+	util.TablePrinter(util.SnapshotColumnDefinations, rows, printers.PrintOptions{Wide: false})
+	return nil
+}
+
+// NewCmdSnapshotDescribe describes a single VolumeSnapshot, e.g.:
+//
+//	openebsctl snapshot describe mysql-data-snap
+func NewCmdSnapshotDescribe() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe",
+		Short: "Describe a VolumeSnapshot and its source volume's replica snapshot state",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(runSnapshotDescribe(args[0]), util.Fatal)
+		},
+	}
+}
+
+func runSnapshotDescribe(name string) error {
+	c, err := client.NewK8sClient("")
+	if err != nil {
+		return err
+	}
+	return snapshot.DescribeSnapshot(c, snapNamespace, name)
+}
+
+// NewCmdSnapshotCreate creates a VolumeSnapshot, e.g.:
+//
+//	openebsctl snapshot create mysql-data-snap --pvc mysql-data --class cstor-snapshotclass
+func NewCmdSnapshotCreate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a VolumeSnapshot of a cstor/jiva PVC",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(runSnapshotCreate(args[0]), util.Fatal)
+		},
+	}
+	cmd.Flags().StringVar(&snapSourcePVC, "pvc", "", "source PVC to snapshot (required)")
+	cmd.Flags().StringVar(&snapClassName, "class", "", "VolumeSnapshotClass to use (required)")
+	return cmd
+}
+
+func runSnapshotCreate(name string) error {
+	c, err := client.NewK8sClient("")
+	if err != nil {
+		return err
+	}
+	return snapshot.CreateSnapshot(c, snapNamespace, snapSourcePVC, name, snapClassName)
+}
+
+// NewCmdSnapshotDelete deletes a VolumeSnapshot, e.g.:
+//
+//	openebsctl snapshot delete mysql-data-snap
+func NewCmdSnapshotDelete() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a VolumeSnapshot",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(runSnapshotDelete(args[0]), util.Fatal)
+		},
+	}
+}
+
+func runSnapshotDelete(name string) error {
+	c, err := client.NewK8sClient("")
+	if err != nil {
+		return err
+	}
+	return snapshot.DeleteSnapshot(c, snapNamespace, name)
+}
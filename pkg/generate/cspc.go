@@ -0,0 +1,284 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"fmt"
+	"sort"
+
+	cstorv1 "github.com/openebs/api/v2/pkg/apis/cstor/v1"
+	"github.com/openebs/api/v2/pkg/apis/openebs.io/v1alpha1"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/util"
+)
+
+// validPoolTypes are the RaidGroup types openebsctl knows how to lay out
+var validPoolTypes = map[string]bool{
+	"stripe": true,
+	"mirror": true,
+	"raidz":  true,
+	"raidz2": true,
+}
+
+// isPoolTypeValid tells whether the given poolType is supported by makePools
+func isPoolTypeValid(poolType string) bool {
+	return validPoolTypes[poolType]
+}
+
+// gigaBytesToBytes converts a GiB quantity to raw bytes, matching the units
+// the BlockDevice's Spec.Capacity.Storage field is reported in.
+func gigaBytesToBytes(gb int) uint64 {
+	return uint64(gb) * 1024 * 1024 * 1024
+}
+
+// usableCapacity returns the storage a RaidGroup of nDevices devices of size
+// bytesPerDevice would expose to the pool, accounting for the redundancy
+// overhead of poolType.
+func usableCapacity(poolType string, nDevices int, bytesPerDevice uint64) uint64 {
+	switch poolType {
+	case "mirror":
+		return uint64(nDevices/2) * bytesPerDevice
+	case "raidz":
+		return uint64(nDevices-1) * bytesPerDevice
+	case "raidz2":
+		return uint64(nDevices-2) * bytesPerDevice
+	default: // stripe
+		return uint64(nDevices) * bytesPerDevice
+	}
+}
+
+// selectBDsForCapacity picks the smallest-capacity set of nDevices BlockDevices
+// out of candidates that still meets targetBytes of usable capacity for the
+// given poolType. candidates are assumed to already be filtered down to
+// healthy, unclaimed, unformatted devices for a single node.
+//
+// When targetBytes is 0, no capacity constraint is applied and the nDevices
+// smallest devices are returned, which keeps bigger disks free for other
+// pools/uses.
+func selectBDsForCapacity(poolType string, nDevices int, targetBytes uint64, candidates []v1alpha1.BlockDevice) ([]v1alpha1.BlockDevice, error) {
+	if len(candidates) < nDevices {
+		return nil, fmt.Errorf("not enough block devices, need %d, got %d", nDevices, len(candidates))
+	}
+	sorted := make([]v1alpha1.BlockDevice, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Spec.Capacity.Storage < sorted[j].Spec.Capacity.Storage
+	})
+	if targetBytes == 0 {
+		return sorted[:nDevices], nil
+	}
+	// Windows are sorted ascending, so as the window slides towards the
+	// back (bigger devices) usable capacity only grows. Walk from the
+	// smallest-device window onwards and stop at the first one that
+	// meets the target, i.e. the smallest set that still satisfies it.
+	for start := 0; start <= len(sorted)-nDevices; start++ {
+		window := sorted[start : start+nDevices]
+		minSize := window[0].Spec.Capacity.Storage
+		if usableCapacity(poolType, nDevices, minSize) >= targetBytes {
+			return window, nil
+		}
+	}
+	return nil, fmt.Errorf("no combination of %d block devices can satisfy the requested capacity", nDevices)
+}
+
+// makePools builds one PoolSpec per node out of the node -> BlockDevice
+// candidates in bd, selecting nDevices BlockDevices per node according to
+// poolType. targetBytes, when non-zero, additionally requires the selected
+// BlockDevices on every node to provide at least that much usable capacity
+// once poolType's redundancy overhead is accounted for.
+func makePools(poolType string, nDevices int, bd map[string][]v1alpha1.BlockDevice, nodes []string, hostNames []string, targetBytes uint64) (*[]cstorv1.PoolSpec, error) {
+	if !isPoolTypeValid(poolType) {
+		return nil, fmt.Errorf("invalid pool type %s", poolType)
+	}
+	if err := validateStripeWidth(poolType, nDevices); err != nil {
+		return nil, err
+	}
+	var pools []cstorv1.PoolSpec
+	for i, node := range nodes {
+		candidates, ok := bd[node]
+		if !ok || len(candidates) < nDevices {
+			return nil, fmt.Errorf("node %s does not have %d usable block devices", node, nDevices)
+		}
+		selected, err := selectBDsForCapacity(poolType, nDevices, targetBytes, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %v", node, err)
+		}
+		var bdNames []cstorv1.CStorPoolInstanceBlockDevice
+		for _, d := range selected {
+			bdNames = append(bdNames, cstorv1.CStorPoolInstanceBlockDevice{BlockDeviceName: d.Name})
+		}
+		pools = append(pools, cstorv1.PoolSpec{
+			NodeSelector: map[string]string{string(util.HostNameLabel): hostNames[i]},
+			DataRaidGroups: []cstorv1.RaidGroup{
+				{Type: poolType, CStorPoolInstanceBlockDevices: bdNames},
+			},
+			PoolConfig: cstorv1.PoolConfig{DataRaidGroupType: poolType},
+		})
+	}
+	return &pools, nil
+}
+
+// validateStripeWidth enforces the device-count shapes each RaidGroup type
+// requires, returning a descriptive error when nDevices doesn't line up.
+func validateStripeWidth(poolType string, nDevices int) error {
+	switch poolType {
+	case "mirror":
+		if nDevices < 2 || nDevices%2 != 0 {
+			return fmt.Errorf("mirror pools need an even number of block devices >= 2, got %d", nDevices)
+		}
+	case "raidz":
+		if nDevices < 3 || nDevices%2 == 0 {
+			return fmt.Errorf("raidz pools need an odd number of block devices >= 3, got %d", nDevices)
+		}
+	case "raidz2":
+		if nDevices < 6 || nDevices%2 != 0 {
+			return fmt.Errorf("raidz2 pools need an even number of block devices >= 6, got %d", nDevices)
+		}
+	case "stripe":
+		if nDevices < 1 {
+			return fmt.Errorf("stripe pools need at least 1 block device, got %d", nDevices)
+		}
+	}
+	return nil
+}
+
+// cspc fetches the suggested nodes & their usable BlockDevices and builds a
+// CStorPoolCluster spec from them. GB, when non-zero, is the target usable
+// capacity (in GiB) each generated pool must provide; devs BlockDevices are
+// greedily chosen per node to be the smallest set meeting that target. When
+// withPDB is true, the returned YAML also carries a companion
+// PodDisruptionBudget protecting the generated CSPI pods, as a second
+// document in the same bundle.
+func cspc(c *client.K8sClient, nodes []string, devs int, poolType string, GB int, withPDB bool) (*cstorv1.CStorPoolCluster, string, error) {
+	if !isPoolTypeValid(poolType) {
+		return nil, "", fmt.Errorf("invalid pool type %s, must be one of stripe, mirror, raidz, raidz2", poolType)
+	}
+	openebsNS, err := getCstorNamespace(c)
+	if err != nil {
+		return nil, "", err
+	}
+	c.Ns = openebsNS
+	hostNames, err := validateNodes(c, nodes)
+	if err != nil {
+		return nil, "", err
+	}
+	bdMap, err := getUsableBDsPerNode(c, nodes)
+	if err != nil {
+		return nil, "", err
+	}
+	pools, err := makePools(poolType, devs, bdMap, nodes, hostNames, gigaBytesToBytes(GB))
+	if err != nil {
+		return nil, "", err
+	}
+	cspc := &cstorv1.CStorPoolCluster{
+		TypeMeta: metav1.TypeMeta{Kind: "CStorPoolCluster", APIVersion: "cstor.openebs.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cspc-" + poolType,
+			Namespace: openebsNS,
+		},
+		Spec: cstorv1.CStorPoolClusterSpec{Pools: *pools},
+	}
+	out, err := yaml.Marshal(cspc)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal generated CSPC to YAML: %v", err)
+	}
+	if !withPDB {
+		return cspc, string(out), nil
+	}
+	bundle, err := marshalPDBBundle(string(out), generatePDB(cspc, poolType))
+	if err != nil {
+		return nil, "", err
+	}
+	return cspc, bundle, nil
+}
+
+// Cspc is the exported entrypoint `openebsctl cspc generate` calls into. It
+// generates a CStorPoolCluster (and, with withPDB, a companion PDB) for
+// nodes using devs BlockDevices per pool of poolType, targeting sizeGi GiB
+// of usable capacity per pool when sizeGi is non-zero, and returns the
+// generated YAML ready to print or pipe into `kubectl apply -f -`.
+func Cspc(c *client.K8sClient, nodes []string, devs int, poolType string, sizeGi int, withPDB bool) (string, error) {
+	_, out, err := cspc(c, nodes, devs, poolType, sizeGi, withPDB)
+	return out, err
+}
+
+// getCstorNamespace figures out the namespace cstor is actually installed in
+// by looking at the running cstor CSI controller pod, ignoring whatever
+// namespace the caller/kubeconfig happened to be scoped to.
+func getCstorNamespace(c *client.K8sClient) (string, error) {
+	pods, err := c.K8sCS.CoreV1().Pods("").List(util.Context(), metav1.ListOptions{
+		LabelSelector: util.CstorCSIControllerLabel,
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return "", fmt.Errorf("no cstor installation found in the cluster")
+	}
+	return pods.Items[0].Namespace, nil
+}
+
+// validateNodes ensures every requested node actually exists and returns
+// their kubernetes.io/hostname labels, in the same order as nodes.
+func validateNodes(c *client.K8sClient, nodes []string) ([]string, error) {
+	hostNames := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		node, err := c.K8sCS.CoreV1().Nodes().Get(util.Context(), n, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("node %s not found in the cluster", n)
+		}
+		hostName := node.Labels[string(util.HostNameLabel)]
+		if hostName == "" {
+			hostName = node.Name
+		}
+		hostNames = append(hostNames, hostName)
+	}
+	return hostNames, nil
+}
+
+// getUsableBDsPerNode lists active, unclaimed, unformatted BlockDevices for
+// each of the given nodes.
+func getUsableBDsPerNode(c *client.K8sClient, nodes []string) (map[string][]v1alpha1.BlockDevice, error) {
+	bds, err := c.OpenebsCS.OpenebsV1alpha1().BlockDevices(c.Ns).List(util.Context(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block devices in namespace %s", c.Ns)
+	}
+	nodeSet := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		nodeSet[n] = false
+	}
+	bdMap := make(map[string][]v1alpha1.BlockDevice)
+	for _, bd := range bds.Items {
+		node := bd.Spec.NodeAttributes.NodeName
+		if _, ok := nodeSet[node]; !ok {
+			continue
+		}
+		if bd.Status.State != v1alpha1.BlockDeviceActive ||
+			bd.Status.ClaimState != v1alpha1.BlockDeviceUnclaimed ||
+			bd.Spec.FileSystem.Type != "" {
+			continue
+		}
+		bdMap[node] = append(bdMap[node], bd)
+		nodeSet[node] = true
+	}
+	for _, n := range nodes {
+		if !nodeSet[n] {
+			return nil, fmt.Errorf("no usable block devices found on node %s", n)
+		}
+	}
+	return bdMap, nil
+}
@@ -35,6 +35,7 @@ func TestCSPC(t *testing.T) {
 		nodes    []string
 		devs     int
 		GB       int
+		withPDB  bool
 		poolType string
 	}
 	tests := []struct {
@@ -111,8 +112,7 @@ func TestCSPC(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// tt.args.GB,
-			got, got1, err := cspc(tt.args.c, tt.args.nodes, tt.args.devs, tt.args.poolType)
+			got, got1, err := cspc(tt.args.c, tt.args.nodes, tt.args.devs, tt.args.poolType, tt.args.GB, tt.args.withPDB)
 			assert.YAMLEq(t, tt.str, got1, "stringified YAML is not the same as expected")
 			assert.EqualValues(t, got, tt.want, "struct is not same")
 			if (err != nil) != tt.wantErr {
@@ -151,11 +151,12 @@ func Test_isPoolTypeValid(t *testing.T) {
 
 func Test_makePools(t *testing.T) {
 	type args struct {
-		poolType string
-		nDevices int
-		bd       map[string][]v1alpha1.BlockDevice
-		nodes    []string
-		hosts    []string
+		poolType    string
+		nDevices    int
+		bd          map[string][]v1alpha1.BlockDevice
+		nodes       []string
+		hosts       []string
+		targetBytes uint64
 	}
 	tests := []struct {
 		name    string
@@ -180,10 +181,31 @@ func Test_makePools(t *testing.T) {
 			map[string][]v1alpha1.BlockDevice{"node1": {goodBD1N1, goodBD2N1},
 				"node2": {goodBD1N2, goodBD2N2}, "node3": {goodBD1N3, goodBD2N3}},
 			[]string{"node1", "node2", "node3"}, []string{"node1", "node2", "node3"}}, nil, true},
+		{"raidz, one node, three disks", args{"raidz", 3,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1, raidzBD3N1}},
+			[]string{"node1"}, []string{"node1"}}, &raidzCSPCThreeBDs, false},
+		{"raidz, one node, five disks", args{"raidz", 5,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1, raidzBD5N1}},
+			[]string{"node1"}, []string{"node1"}}, &raidzCSPCFiveBDs, false},
+		{"raidz, one node, even disks is invalid", args{"raidz", 4,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1}},
+			[]string{"node1"}, []string{"node1"}}, nil, true},
+		{"raidz, one node, two disks is below the minimum", args{"raidz", 2,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1}},
+			[]string{"node1"}, []string{"node1"}}, nil, true},
+		{"raidz2, one node, six disks", args{"raidz2", 6,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1, raidzBD5N1, raidzBD6N1}},
+			[]string{"node1"}, []string{"node1"}}, &raidz2CSPCSixBDs, false},
+		{"raidz2, one node, odd disks is invalid", args{"raidz2", 5,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1, raidzBD5N1}},
+			[]string{"node1"}, []string{"node1"}}, nil, true},
+		{"raidz2, one node, four disks is below the minimum", args{"raidz2", 4,
+			map[string][]v1alpha1.BlockDevice{"node1": {raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1}},
+			[]string{"node1"}, []string{"node1"}}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := makePools(tt.args.poolType, tt.args.nDevices, tt.args.bd, tt.args.nodes, tt.args.hosts)
+			got, err := makePools(tt.args.poolType, tt.args.nDevices, tt.args.bd, tt.args.nodes, tt.args.hosts, tt.args.targetBytes)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("makePools() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -194,4 +216,117 @@ func Test_makePools(t *testing.T) {
 			assert.Equal(t, tt.want, got, "", nil)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// capBD builds a throwaway BlockDevice of the given size (in GiB) for
+// capacity-selection tests, where only the name and size matter.
+func capBD(name string, gib uint64) v1alpha1.BlockDevice {
+	bd := v1alpha1.BlockDevice{}
+	bd.Name = name
+	bd.Spec.Capacity.Storage = gib * 1024 * 1024 * 1024
+	return bd
+}
+
+// Same-size BDs on node1, used purely to exercise makePools' raidz/raidz2
+// stripe-width validation; their capacity is irrelevant here.
+var (
+	raidzBD1N1 = capBD("raidz-bd1-n1", 10)
+	raidzBD2N1 = capBD("raidz-bd2-n1", 10)
+	raidzBD3N1 = capBD("raidz-bd3-n1", 10)
+	raidzBD4N1 = capBD("raidz-bd4-n1", 10)
+	raidzBD5N1 = capBD("raidz-bd5-n1", 10)
+	raidzBD6N1 = capBD("raidz-bd6-n1", 10)
+
+	// raidzCapacityCandidates/raidz2CapacityCandidates give the selection
+	// algorithm two same-size groups of devices so the "smallest window
+	// that clears the target" behaviour is actually exercised.
+	raidzCapacityCandidates = []v1alpha1.BlockDevice{
+		capBD("rz-10a", 10), capBD("rz-10b", 10), capBD("rz-10c", 10),
+		capBD("rz-50a", 50), capBD("rz-50b", 50), capBD("rz-50c", 50),
+		capBD("rz-100a", 100), capBD("rz-100b", 100), capBD("rz-100c", 100),
+	}
+	raidz2CapacityCandidates = []v1alpha1.BlockDevice{
+		capBD("rz2-10a", 10), capBD("rz2-10b", 10), capBD("rz2-10c", 10),
+		capBD("rz2-10d", 10), capBD("rz2-10e", 10), capBD("rz2-10f", 10),
+		capBD("rz2-50a", 50), capBD("rz2-50b", 50), capBD("rz2-50c", 50),
+		capBD("rz2-50d", 50), capBD("rz2-50e", 50), capBD("rz2-50f", 50),
+	}
+
+	raidzCSPCThreeBDs = []cstorv1.PoolSpec{raidzPoolSpec("raidz", raidzBD1N1, raidzBD2N1, raidzBD3N1)}
+	raidzCSPCFiveBDs  = []cstorv1.PoolSpec{raidzPoolSpec("raidz", raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1, raidzBD5N1)}
+	raidz2CSPCSixBDs  = []cstorv1.PoolSpec{raidzPoolSpec("raidz2", raidzBD1N1, raidzBD2N1, raidzBD3N1, raidzBD4N1, raidzBD5N1, raidzBD6N1)}
+)
+
+// raidzPoolSpec builds the single-node PoolSpec makePools is expected to
+// produce for a raidz/raidz2 RaidGroup made up of bds.
+func raidzPoolSpec(poolType string, bds ...v1alpha1.BlockDevice) cstorv1.PoolSpec {
+	var devices []cstorv1.CStorPoolInstanceBlockDevice
+	for _, bd := range bds {
+		devices = append(devices, cstorv1.CStorPoolInstanceBlockDevice{BlockDeviceName: bd.Name})
+	}
+	return cstorv1.PoolSpec{
+		NodeSelector:   map[string]string{"kubernetes.io/hostname": "node1"},
+		DataRaidGroups: []cstorv1.RaidGroup{{Type: poolType, CStorPoolInstanceBlockDevices: devices}},
+		PoolConfig:     cstorv1.PoolConfig{DataRaidGroupType: poolType},
+	}
+}
+
+func Test_selectBDsForCapacity(t *testing.T) {
+	tenGi, fiftyGi, hundredGi := capBD("bd-10g", 10), capBD("bd-50g", 50), capBD("bd-100g", 100)
+	type args struct {
+		poolType    string
+		nDevices    int
+		targetBytes uint64
+		candidates  []v1alpha1.BlockDevice
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantNames []string
+		wantErr   bool
+	}{
+		{"stripe, under capacity picks smallest 2 disks", args{"stripe", 2, 0,
+			[]v1alpha1.BlockDevice{tenGi, fiftyGi, hundredGi}}, []string{"bd-10g", "bd-50g"}, false},
+		{"stripe, exact capacity picked by smallest combination", args{"stripe", 1, 50 * 1024 * 1024 * 1024,
+			[]v1alpha1.BlockDevice{tenGi, fiftyGi, hundredGi}}, []string{"bd-50g"}, false},
+		{"stripe, over capacity falls back to biggest disk", args{"stripe", 1, 60 * 1024 * 1024 * 1024,
+			[]v1alpha1.BlockDevice{tenGi, fiftyGi, hundredGi}}, []string{"bd-100g"}, false},
+		{"stripe, unsatisfiable capacity errors out", args{"stripe", 1, 200 * 1024 * 1024 * 1024,
+			[]v1alpha1.BlockDevice{tenGi, fiftyGi, hundredGi}}, nil, true},
+		{"mirror, usable capacity is halved so the smaller disk still clears the target", args{"mirror", 2, 50 * 1024 * 1024 * 1024,
+			[]v1alpha1.BlockDevice{tenGi, fiftyGi, hundredGi, capBD("bd-100g-2", 100)}}, []string{"bd-50g", "bd-100g"}, false},
+		{"raidz, under capacity picks the smallest 3 disks", args{"raidz", 3, 0, raidzCapacityCandidates},
+			[]string{"rz-10a", "rz-10b", "rz-10c"}, false},
+		{"raidz, exact capacity needs the parity-adjusted target", args{"raidz", 3, 100 * 1024 * 1024 * 1024, raidzCapacityCandidates},
+			[]string{"rz-50a", "rz-50b", "rz-50c"}, false},
+		{"raidz, over capacity falls back to the biggest disks available", args{"raidz", 3, 150 * 1024 * 1024 * 1024, raidzCapacityCandidates},
+			[]string{"rz-100a", "rz-100b", "rz-100c"}, false},
+		{"raidz, unsatisfiable capacity errors out", args{"raidz", 3, 1000 * 1024 * 1024 * 1024, raidzCapacityCandidates},
+			nil, true},
+		{"raidz2, under capacity picks the smallest 6 disks", args{"raidz2", 6, 0, raidz2CapacityCandidates},
+			[]string{"rz2-10a", "rz2-10b", "rz2-10c", "rz2-10d", "rz2-10e", "rz2-10f"}, false},
+		{"raidz2, exact capacity needs the double-parity-adjusted target", args{"raidz2", 6, 200 * 1024 * 1024 * 1024, raidz2CapacityCandidates},
+			[]string{"rz2-50a", "rz2-50b", "rz2-50c", "rz2-50d", "rz2-50e", "rz2-50f"}, false},
+		{"raidz2, over capacity also falls back to the only disks big enough", args{"raidz2", 6, 150 * 1024 * 1024 * 1024, raidz2CapacityCandidates},
+			[]string{"rz2-50a", "rz2-50b", "rz2-50c", "rz2-50d", "rz2-50e", "rz2-50f"}, false},
+		{"raidz2, unsatisfiable capacity errors out", args{"raidz2", 6, 1000 * 1024 * 1024 * 1024, raidz2CapacityCandidates},
+			nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectBDsForCapacity(tt.args.poolType, tt.args.nDevices, tt.args.targetBytes, tt.args.candidates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("selectBDsForCapacity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			var gotNames []string
+			for _, bd := range got {
+				gotNames = append(gotNames, bd.Name)
+			}
+			assert.ElementsMatch(t, tt.wantNames, gotNames)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrate generates the manifests needed to move a PVC from one
+// OpenEBS storage engine to another (jiva, cstor, mayastor): a destination
+// PVC on an existing compatible StorageClass, an rsync Job that copies the data across (a snapshot
+// taken by the source engine's CSI driver can't be restored through a
+// different driver, so cross-engine moves always copy via a Job), and a
+// cutover patch for whatever workload mounts it.
+package migrate
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/util"
+)
+
+// engineCSIDrivers maps an engine name, as accepted by --from/--to, to its
+// CSI driver name.
+var engineCSIDrivers = map[string]string{
+	"jiva":     util.JivaCSIDriver,
+	"cstor":    util.CstorCSIDriver,
+	"mayastor": util.MayastorCSIDriver,
+}
+
+// engineAccessModes lists the access modes each engine's CSI driver
+// supports. Picking a destination StorageClass that can't honour one of
+// the source PVC's access modes would produce a PVC that never binds, so
+// this is checked before any StorageClass is considered.
+var engineAccessModes = map[string][]corev1.PersistentVolumeAccessMode{
+	"jiva":     {corev1.ReadWriteOnce},
+	"cstor":    {corev1.ReadWriteOnce, corev1.ReadOnlyMany},
+	"mayastor": {corev1.ReadWriteOnce},
+}
+
+// engineReplicaParam is the StorageClass parameter each engine uses to pin
+// its replica count, used to prefer a destination StorageClass that
+// replicates the source volume's data the same number of times.
+var engineReplicaParam = map[string]string{
+	"jiva":  "replicaCount",
+	"cstor": "cstor.openebs.io/replica-count",
+}
+
+// Plan is one `openebsctl migrate plan` invocation.
+type Plan struct {
+	C         *client.K8sClient
+	From      string
+	To        string
+	Namespace string
+	PVC       string
+	Apply     bool
+}
+
+// summary is the dry-run table printed before any manifests are emitted.
+type summary struct {
+	SourceEngine string
+	SourceSize   string
+	SourceRepl   int32
+	DestEngine   string
+	DestSize     string
+	DestRepl     int32
+	DataToCopy   string
+}
+
+// Run resolves the source PVC/PV, picks a compatible destination
+// StorageClass, prints a dry-run summary and returns the generated YAML
+// bundle. When p.Apply is true, only the destination PVC is created in the
+// cluster and the bundle is returned without being printed; otherwise the
+// bundle is written to stdout. Cutover is always left to the user.
+func (p *Plan) Run() (string, error) {
+	if p.From == p.To {
+		return "", fmt.Errorf("--from and --to must name different engines")
+	}
+	srcDriver, ok := engineCSIDrivers[p.From]
+	if !ok {
+		return "", fmt.Errorf("unknown source engine %s", p.From)
+	}
+	dstDriver, ok := engineCSIDrivers[p.To]
+	if !ok {
+		return "", fmt.Errorf("unknown destination engine %s", p.To)
+	}
+	pvc, err := p.C.GetPVC(p.Namespace, p.PVC)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PVC %s/%s", p.Namespace, p.PVC)
+	}
+	pv, err := p.C.GetPV(pvc.Spec.VolumeName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PV %s bound to %s/%s", pvc.Spec.VolumeName, p.Namespace, p.PVC)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != srcDriver {
+		return "", fmt.Errorf("PVC %s/%s is not backed by the %s engine", p.Namespace, p.PVC, p.From)
+	}
+	srcRepl := sourceReplicaCount(p.C, p.From, pv.Name)
+	destSC, err := pickDestinationStorageClass(p.C, p.To, dstDriver, pvc, srcRepl)
+	if err != nil {
+		return "", err
+	}
+	destPVCName := pvc.Name + "-" + p.To
+	destPVC := newPVC(destPVCName, p.Namespace, destSC.Name, pvc)
+	// From and To are always different engines (checked above), and a
+	// VolumeSnapshot taken by one engine's CSI driver can't be restored into
+	// a PVC provisioned by a different driver, so the copy always goes
+	// through an rsync Job rather than a snapshot-backed DataSource.
+	bundle := []interface{}{destPVC, newCopyJob(pvc.Name, destPVCName, p.Namespace)}
+	bundle = append(bundle, newCutoverPatch(pvc.Name, destPVCName, p.Namespace))
+	printSummary(summary{
+		SourceEngine: p.From,
+		SourceSize:   pvc.Spec.Resources.Requests.Storage().String(),
+		SourceRepl:   srcRepl,
+		DestEngine:   p.To,
+		DestSize:     pvc.Spec.Resources.Requests.Storage().String(),
+		DestRepl:     destReplicaCount(destSC, p.To, srcRepl),
+		DataToCopy:   pvc.Status.Capacity.Storage().String(),
+	})
+	out, err := marshalBundle(bundle)
+	if err != nil {
+		return "", err
+	}
+	if p.Apply {
+		return out, applyBundle(p.C, p.Namespace, destPVC)
+	}
+	fmt.Println(out)
+	return out, nil
+}
+
+// pickDestinationStorageClass finds a StorageClass provisioned by dstDriver
+// that can serve src: dstEngine must support every access mode src
+// requests, and among the StorageClasses that pass that check, one whose
+// replica-count parameter matches srcRepl is preferred so the destination
+// volume keeps the same redundancy as the source. Capacity is never a
+// filtering criterion here because the destination PVC always requests the
+// same Resources as src (see newPVC); it isn't pinned by the StorageClass.
+func pickDestinationStorageClass(c *client.K8sClient, dstEngine, dstDriver string, src *corev1.PersistentVolumeClaim, srcRepl int32) (*storagev1.StorageClass, error) {
+	if !accessModesSupported(dstEngine, src.Spec.AccessModes) {
+		return nil, fmt.Errorf("destination engine %s does not support access mode(s) %v requested by %s", dstEngine, src.Spec.AccessModes, src.Name)
+	}
+	scs, err := c.K8sCS.StorageV1().StorageClasses().List(util.Context(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storageclasses")
+	}
+	replParam := engineReplicaParam[dstEngine]
+	var fallback *storagev1.StorageClass
+	for i := range scs.Items {
+		sc := &scs.Items[i]
+		if sc.Provisioner != dstDriver {
+			continue
+		}
+		if fallback == nil {
+			fallback = sc
+		}
+		if replParam != "" && srcRepl != 0 && sc.Parameters[replParam] == fmt.Sprintf("%d", srcRepl) {
+			return sc, nil
+		}
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, fmt.Errorf("no StorageClass provisioned by %s found for the destination engine", dstDriver)
+}
+
+// accessModesSupported reports whether every access mode in modes is
+// usable with engine.
+func accessModesSupported(engine string, modes []corev1.PersistentVolumeAccessMode) bool {
+	supported := engineAccessModes[engine]
+	for _, m := range modes {
+		ok := false
+		for _, s := range supported {
+			if m == s {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceReplicaCount looks up how many replicas back pvName on engine, so
+// the migration can report redundancy loss/gain and pick a destination
+// StorageClass with a matching replica count. Engines this package doesn't
+// know how to introspect (e.g. mayastor) report 0, which disables the
+// replica-count preference rather than failing the migration.
+func sourceReplicaCount(c *client.K8sClient, engine, pvName string) int32 {
+	switch engine {
+	case "cstor":
+		if cv, err := c.GetCV(pvName); err == nil {
+			return int32(cv.Spec.ReplicationFactor)
+		}
+	case "jiva":
+		if jv, err := c.GetJV(pvName); err == nil {
+			return int32(jv.Spec.Policy.Target.ReplicationFactor)
+		}
+	}
+	return 0
+}
+
+// destReplicaCount reports the replica count the chosen destination
+// StorageClass will actually provision: its own replica-count parameter if
+// it sets one, otherwise srcRepl since the source's redundancy carries over
+// unchanged.
+func destReplicaCount(sc *storagev1.StorageClass, engine string, srcRepl int32) int32 {
+	replParam := engineReplicaParam[engine]
+	if replParam == "" {
+		return srcRepl
+	}
+	if v, ok := sc.Parameters[replParam]; ok {
+		var n int32
+		if _, err := fmt.Sscanf(v, "%d", &n); err == nil {
+			return n
+		}
+	}
+	return srcRepl
+}
+
+func newPVC(name, ns, scName string, src *corev1.PersistentVolumeClaim) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      src.Spec.AccessModes,
+			StorageClassName: &scName,
+			Resources:        src.Spec.Resources,
+		},
+	}
+}
+
+func newCopyJob(srcPVC, destPVC, ns string) *batchv1.Job {
+	const mountSrc, mountDst = "/src", "/dst"
+	return &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: srcPVC + "-migrate-copy", Namespace: ns},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "rsync",
+						Image:   "eeacms/rsync",
+						Command: []string{"rsync", "-avh", mountSrc + "/", mountDst},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "src", MountPath: mountSrc},
+							{Name: "dst", MountPath: mountDst},
+						},
+					}},
+					Volumes: []corev1.Volume{
+						{Name: "src", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: srcPVC}}},
+						{Name: "dst", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: destPVC}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// cutoverPatchStub describes the strategic-merge patch a user applies to
+// their Deployment to swap its volume reference to the new PVC.
+type cutoverPatchStub struct {
+	Spec struct {
+		Template struct {
+			Spec struct {
+				Volumes []corev1.Volume `yaml:"volumes"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+func newCutoverPatch(srcPVC, destPVC, _ string) cutoverPatchStub {
+	var patch cutoverPatchStub
+	patch.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name:         srcPVC,
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: destPVC}},
+	}}
+	return patch
+}
+
+func marshalBundle(docs []interface{}) (string, error) {
+	var out string
+	for i, doc := range docs {
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal migration manifest: %v", err)
+		}
+		if i > 0 {
+			out += "---\n"
+		}
+		out += string(b)
+	}
+	return out, nil
+}
+
+func printSummary(s summary) {
+	fmt.Println("Migration Plan Summary :")
+	fmt.Println("------------------------")
+	fmt.Printf("%-10s  %-10s  %-10s\n", "", "SOURCE", "DESTINATION")
+	fmt.Printf("%-10s  %-10s  %-10s\n", "ENGINE", s.SourceEngine, s.DestEngine)
+	fmt.Printf("%-10s  %-10s  %-10s\n", "SIZE", s.SourceSize, s.DestSize)
+	fmt.Printf("%-10s  %-10d  %-10d\n", "REPLICAS", s.SourceRepl, s.DestRepl)
+	fmt.Printf("estimated data to copy: %s\n\n", s.DataToCopy)
+}
+
+// applyBundle creates the destination PVC directly against the cluster;
+// the copy Job and cutover patch are left for the operator to apply
+// once the data copy has been reviewed.
+func applyBundle(c *client.K8sClient, ns string, destPVC *corev1.PersistentVolumeClaim) error {
+	_, err := c.K8sCS.CoreV1().PersistentVolumeClaims(ns).Create(util.Context(), destPVC, metav1.CreateOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to create destination PVC %s/%s: %v\n", ns, destPVC.Name, err)
+		return err
+	}
+	fmt.Printf("persistentvolumeclaim %s/%s created\n", ns, destPVC.Name)
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"fmt"
+
+	cstorv1 "github.com/openebs/api/v2/pkg/apis/cstor/v1"
+	"gopkg.in/yaml.v2"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// generatePDB builds the PodDisruptionBudget that should protect the CSPI
+// pods belonging to cspc. Mirror/raidz/raidz2 topologies get a minAvailable
+// budget of ceil(nPools/2)+1, one better than a bare majority, capped at
+// nPools-1 so at least one pool can always be voluntarily disrupted (e.g.
+// to drain a node) even for small clusters; stripe pools have no
+// redundancy, so losing even one pool is unsafe and maxUnavailable is
+// pinned at 0.
+func generatePDB(cspc *cstorv1.CStorPoolCluster, poolType string) *policyv1.PodDisruptionBudget {
+	nPools := len(cspc.Spec.Pools)
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"openebs.io/cstor-pool-cluster": cspc.Name,
+		},
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{Kind: "PodDisruptionBudget", APIVersion: "policy/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cspc.Name + "-pdb",
+			Namespace: cspc.Namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{Selector: selector},
+	}
+	if poolType == "stripe" {
+		zero := intstr.FromInt(0)
+		pdb.Spec.MaxUnavailable = &zero
+	} else {
+		// ceil(nPools/2) == (nPools+1)/2 for integer nPools.
+		n := (nPools+1)/2 + 1
+		// Cap at nPools-1 so the PDB never demands every pool be available;
+		// otherwise small clusters (nPools <= 2) get a minAvailable >= nPools
+		// that permanently blocks voluntary disruptions like node drains.
+		if max := nPools - 1; n > max {
+			n = max
+		}
+		if n < 0 {
+			n = 0
+		}
+		minAvailable := intstr.FromInt(n)
+		pdb.Spec.MinAvailable = &minAvailable
+	}
+	return pdb
+}
+
+// marshalPDBBundle renders cspcYAML followed by pdb as a single multi-document
+// YAML bundle that can be applied with `kubectl apply -f -`.
+func marshalPDBBundle(cspcYAML string, pdb *policyv1.PodDisruptionBudget) (string, error) {
+	pdbYAML, err := yaml.Marshal(pdb)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal generated PodDisruptionBudget to YAML: %v", err)
+	}
+	return cspcYAML + "---\n" + string(pdbYAML), nil
+}
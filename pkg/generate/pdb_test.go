@@ -0,0 +1,65 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"testing"
+
+	cstorv1 "github.com/openebs/api/v2/pkg/apis/cstor/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_generatePDB(t *testing.T) {
+	newCSPC := func(name string, nPools int) *cstorv1.CStorPoolCluster {
+		return &cstorv1.CStorPoolCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openebs"},
+			Spec:       cstorv1.CStorPoolClusterSpec{Pools: make([]cstorv1.PoolSpec, nPools)},
+		}
+	}
+	tests := []struct {
+		name               string
+		cspc               *cstorv1.CStorPoolCluster
+		poolType           string
+		wantMinAvailable   *int
+		wantMaxUnavailable *int
+	}{
+		{"stripe pools have no quorum to protect, maxUnavailable 0", newCSPC("cspc-stripe", 3), "stripe", nil, intPtr(0)},
+		{"mirror, 1 pool is capped at nPools-1=0 so a disruption is always allowed", newCSPC("cspc-mirror-1", 1), "mirror", intPtr(0), nil},
+		{"mirror, 2 pools: ceil(2/2)+1=2 capped at nPools-1=1", newCSPC("cspc-mirror-2", 2), "mirror", intPtr(1), nil},
+		{"mirror, 3 pools needs ceil(3/2)+1=3, capped at nPools-1=2", newCSPC("cspc-mirror", 3), "mirror", intPtr(2), nil},
+		{"raidz, 5 pools needs ceil(5/2)+1=4, equal to the nPools-1=4 cap", newCSPC("cspc-raidz", 5), "raidz", intPtr(4), nil},
+		{"raidz2, 2 pools: ceil(2/2)+1=2 capped at nPools-1=1", newCSPC("cspc-raidz2", 2), "raidz2", intPtr(1), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdb := generatePDB(tt.cspc, tt.poolType)
+			assert.Equal(t, tt.cspc.Name+"-pdb", pdb.Name)
+			assert.Equal(t, tt.cspc.Name, pdb.Spec.Selector.MatchLabels["openebs.io/cstor-pool-cluster"])
+			if tt.wantMinAvailable != nil {
+				assert.Equal(t, *tt.wantMinAvailable, pdb.Spec.MinAvailable.IntValue())
+				assert.Nil(t, pdb.Spec.MaxUnavailable)
+			}
+			if tt.wantMaxUnavailable != nil {
+				assert.Equal(t, *tt.wantMaxUnavailable, pdb.Spec.MaxUnavailable.IntValue())
+				assert.Nil(t, pdb.Spec.MinAvailable)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int { return &i }
@@ -0,0 +1,143 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CstorVolInfoTemplate to store the cstor volume and pvc describe related details
+	CstorVolInfoTemplate = `
+{{.Name}} Details :
+-----------------
+NAME            : {{.Name}}
+ACCESS MODE     : {{.AccessMode}}
+CSI DRIVER      : {{.CSIDriver}}
+STORAGE CLASS   : {{.StorageClass}}
+VOLUME PHASE    : {{.VolumePhase }}
+VERSION         : {{.Version}}
+SIZE            : {{.Size}}
+STATUS          : {{.Status}}
+REPLICA COUNT	: {{.ReplicaCount}}
+
+`
+)
+
+// GetCstorVolumes returns a list of CStorVolumes
+func GetCstorVolumes(c *client.K8sClient, pvList *corev1.PersistentVolumeList, openebsNS string) ([]metav1.TableRow, error) {
+	var rows []metav1.TableRow
+	for _, pv := range pvList.Items {
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != util.CstorCSIDriver {
+			continue
+		}
+		cv, err := c.GetCV(pv.Name)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "couldn't find cv "+pv.Name)
+			continue
+		}
+		if openebsNS != "" && openebsNS != cv.Namespace {
+			continue
+		}
+		rows = append(rows, metav1.TableRow{
+			Cells: []interface{}{
+				cv.Namespace, pv.Name, cv.Status.Phase, cv.VersionDetails.Status.Current,
+				pv.Spec.Capacity.Storage(), pv.Spec.StorageClassName, pv.Status.Phase,
+				pv.Spec.AccessModes[0], cv.Spec.ReplicationFactor},
+		})
+	}
+	return rows, nil
+}
+
+// DescribeCstorVolume describes a cstor storage engine PersistentVolume
+func DescribeCstorVolume(c *client.K8sClient, vol *corev1.PersistentVolume) error {
+	// 1. Get the CStorVolume corresponding to the pv name
+	cv, err := c.GetCV(vol.Name)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to get CStorVolume for %s\n", vol.Name)
+		return err
+	}
+	// 2. Fill in CStorVolume related details
+	cstorVolInfo := util.VolumeInfo{
+		AccessMode:   util.AccessModeToString(vol.Spec.AccessModes),
+		Capacity:     util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
+		CSIDriver:    vol.Spec.CSI.Driver,
+		Name:         cv.Name,
+		Namespace:    cv.Namespace,
+		PVC:          vol.Spec.ClaimRef.Name,
+		ReplicaCount: int64(cv.Spec.ReplicationFactor),
+		VolumePhase:  vol.Status.Phase,
+		StorageClass: vol.Spec.StorageClassName,
+		Version:      cv.VersionDetails.Status.Current,
+		Size:         util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
+		Status:       string(cv.Status.Phase),
+	}
+	// 3. Print the Volume information
+	_ = util.PrintByTemplate("cstorVolumeInfo", CstorVolInfoTemplate, cstorVolInfo)
+
+	// 4. Fetch the CStor target and replica pod details
+	podList, err := c.GetCVTargetPod(vol.Name)
+	if err == nil {
+		fmt.Println("Target and Replica Pod Details :")
+		fmt.Println("--------------------------------")
+		var rows []metav1.TableRow
+		for _, pod := range podList.Items {
+			rows = append(rows, metav1.TableRow{Cells: []interface{}{
+				pod.Namespace, pod.Name, pod.Spec.NodeName, pod.Status.Phase, pod.Status.PodIP,
+				util.GetReadyContainers(pod.Status.ContainerStatuses),
+				util.Duration(time.Since(pod.ObjectMeta.CreationTimestamp.Time))}})
+		}
+		util.TablePrinter(util.CstorPodDetailsColumnDefinations, rows, printers.PrintOptions{Wide: true})
+	} else {
+		fmt.Println("Target and Replica Pod Details :")
+		fmt.Println("--------------------------------")
+		fmt.Println("No Target and Replica pod exists for the CStorVolume")
+	}
+
+	// 5. Fetch the CStorVolumeReplicas and print them
+	cvrs, err := c.GetCVRs(cv.Name)
+	if err != nil || len(cvrs.Items) == 0 {
+		fmt.Printf("No replicas found for the CStorVolume %s", vol.Name)
+	} else {
+		var rows []metav1.TableRow
+		for _, cvr := range cvrs.Items {
+			rows = append(rows, metav1.TableRow{Cells: []interface{}{
+				cvr.Name, cvr.Status.Phase, cvr.Labels["cstorpool.openebs.io/name"],
+				util.Duration(time.Since(cvr.ObjectMeta.CreationTimestamp.Time))}})
+		}
+		fmt.Println()
+		fmt.Println("Replica Details :")
+		fmt.Println("-----------------")
+		util.TablePrinter(util.CstorReplicaColumnDefinations, rows, printers.PrintOptions{Wide: true})
+	}
+
+	// 6. Print the PDB protecting the target/replica pods, if any
+	selector := "openebs.io/persistent-volume=" + cv.Name
+	if err := DescribePDB(c, cv.Namespace, selector, cv.Name); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to describe PodDisruptionBudget for %s\n", vol.Name)
+	}
+	return nil
+}
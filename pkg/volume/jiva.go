@@ -19,12 +19,15 @@ package volume
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"k8s.io/cli-runtime/pkg/printers"
 
+	"github.com/openebs/api/v2/pkg/apis/openebs.io/v1alpha1"
 	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/snapshot"
 	"github.com/openebs/openebsctl/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,14 +54,159 @@ REPLICA COUNT	: {{.ReplicaCount}}
 	JivaPortalTemplate = `
 Portal Details :
 ------------------
-IQN              :  {{.spec.iscsiSpec.iqn}}
-VOLUME NAME      :  {{.metadata.name}}
-TARGET NODE NAME :  {{.metadata.labels.nodeID}}
-PORTAL           :  {{.spec.iscsiSpec.targetIP}}:{{.spec.iscsiSpec.targetPort}}
+IQN              :  {{.IQN}}
+VOLUME NAME      :  {{.VolumeName}}
+TARGET NODE NAME :  {{.TargetNodeName}}
+PORTAL           :  {{.Portal}}
 
+`
+	// unsupportedVersionBanner is printed instead of crashing when no
+	// jivaSchemaAdapter recognizes a JivaVolume's schema.
+	unsupportedVersionBanner = `
+VERSION NOT FULLY SUPPORTED
+----------------------------
+openebsctl doesn't recognize the schema of this JivaVolume (version %q) and
+can't safely read its fields. Its details have been skipped to avoid
+printing incorrect values.
 `
 )
 
+// portalInfo is the normalized iSCSI portal view printed via
+// JivaPortalTemplate, independent of which JivaVolume schema it came from.
+type portalInfo struct {
+	IQN            string
+	VolumeName     string
+	TargetNodeName string
+	Portal         string
+}
+
+// jivaSchemaAdapter normalizes field access across JivaVolume CR schema
+// versions, so that DescribeJivaVolume doesn't need to know which fields a
+// given CR actually populates.
+type jivaSchemaAdapter interface {
+	// name identifies the schema this adapter handles.
+	name() string
+	// supports reports whether this adapter can safely read jv.
+	supports(version string, jv *v1alpha1.JivaVolume) bool
+	// extract builds the normalized volume + portal info for jv.
+	extract(jv *v1alpha1.JivaVolume, vol *corev1.PersistentVolume) (util.VolumeInfo, portalInfo)
+}
+
+// currentSchemaAdapter handles JivaVolume CRs as shipped by the current
+// CSI-based Jiva, where replication policy and iSCSI details live under
+// Spec.Policy and Spec.ISCSISpec respectively.
+type currentSchemaAdapter struct{}
+
+func (currentSchemaAdapter) name() string { return "current" }
+
+// supports trusts a recognized version string first, since it's the
+// authoritative signal; only when version is empty or unrecognized does it
+// fall back to the populated-fields heuristic.
+func (currentSchemaAdapter) supports(version string, jv *v1alpha1.JivaVolume) bool {
+	switch {
+	case strings.HasPrefix(version, "2.") || strings.HasPrefix(version, "3."):
+		return true
+	case strings.HasPrefix(version, "1."):
+		return false
+	default:
+		return jv.Spec.Policy.Target.ReplicationFactor != 0
+	}
+}
+
+func (currentSchemaAdapter) extract(jv *v1alpha1.JivaVolume, vol *corev1.PersistentVolume) (util.VolumeInfo, portalInfo) {
+	info := util.VolumeInfo{
+		AccessMode:   util.AccessModeToString(vol.Spec.AccessModes),
+		Capacity:     util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
+		CSIDriver:    vol.Spec.CSI.Driver,
+		Name:         jv.Name,
+		Namespace:    jv.Namespace,
+		PVC:          vol.Spec.ClaimRef.Name,
+		ReplicaCount: jv.Spec.Policy.Target.ReplicationFactor,
+		VolumePhase:  vol.Status.Phase,
+		StorageClass: vol.Spec.StorageClassName,
+		Version:      jv.VersionDetails.Status.Current,
+		Size:         util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
+		Status:       jv.Status.Status,
+		JVP:          jv.Annotations["openebs.io/volume-policy"],
+	}
+	portal := portalInfo{
+		IQN:            jv.Spec.ISCSISpec.IQN,
+		VolumeName:     jv.Name,
+		TargetNodeName: jv.Labels["nodeID"],
+		Portal:         fmt.Sprintf("%s:%s", jv.Spec.ISCSISpec.TargetIP, jv.Spec.ISCSISpec.TargetPort),
+	}
+	return info, portal
+}
+
+// legacySchemaAdapter handles pre-CSI, 1.x-era JivaVolume CRs that never
+// populated Spec.Policy, carrying the replication factor and iSCSI IQN as
+// plain labels/annotations instead.
+type legacySchemaAdapter struct{}
+
+func (legacySchemaAdapter) name() string { return "legacy" }
+
+// supports trusts a recognized version string first; only when version is
+// empty or unrecognized does it fall back to the populated-fields
+// heuristic, mirroring currentSchemaAdapter.supports.
+func (legacySchemaAdapter) supports(version string, jv *v1alpha1.JivaVolume) bool {
+	switch {
+	case strings.HasPrefix(version, "1."):
+		return true
+	case strings.HasPrefix(version, "2.") || strings.HasPrefix(version, "3."):
+		return false
+	default:
+		return jv.Spec.Policy.Target.ReplicationFactor == 0 && jv.Labels["replicas"] != ""
+	}
+}
+
+func (legacySchemaAdapter) extract(jv *v1alpha1.JivaVolume, vol *corev1.PersistentVolume) (util.VolumeInfo, portalInfo) {
+	replicaCount, _ := strconv.Atoi(jv.Labels["replicas"])
+	version := jv.Labels["openebs.io/version"]
+	info := util.VolumeInfo{
+		AccessMode:   util.AccessModeToString(vol.Spec.AccessModes),
+		Capacity:     util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
+		CSIDriver:    vol.Spec.CSI.Driver,
+		Name:         jv.Name,
+		Namespace:    jv.Namespace,
+		PVC:          vol.Spec.ClaimRef.Name,
+		ReplicaCount: int64(replicaCount),
+		VolumePhase:  vol.Status.Phase,
+		StorageClass: vol.Spec.StorageClassName,
+		Version:      version,
+		Size:         util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
+		Status:       jv.Status.Status,
+		JVP:          jv.Annotations["openebs.io/volume-policy"],
+	}
+	portal := portalInfo{
+		IQN:            jv.Annotations["openebs.io/iqn"],
+		VolumeName:     jv.Name,
+		TargetNodeName: jv.Labels["nodeID"],
+		Portal:         jv.Annotations["openebs.io/targetportal"],
+	}
+	return info, portal
+}
+
+// jivaSchemaAdapters is consulted in order; the first adapter whose
+// supports() returns true handles the describe.
+var jivaSchemaAdapters = []jivaSchemaAdapter{currentSchemaAdapter{}, legacySchemaAdapter{}}
+
+// detectJivaSchemaAdapter picks the adapter able to read jv's schema,
+// determining the JivaVolume's version from VersionDetails, falling back to
+// the openebs.io/version label, and finally to whichever adapter's
+// heuristic matches the populated spec fields.
+func detectJivaSchemaAdapter(jv *v1alpha1.JivaVolume) jivaSchemaAdapter {
+	version := jv.VersionDetails.Status.Current
+	if version == "" {
+		version = jv.Labels["openebs.io/version"]
+	}
+	for _, a := range jivaSchemaAdapters {
+		if a.supports(version, jv) {
+			return a
+		}
+	}
+	return nil
+}
+
 // GetJiva returns a list of JivaVolumes
 func GetJiva(c *client.K8sClient, pvList *corev1.PersistentVolumeList, openebsNS string) ([]metav1.TableRow, error) {
 	// 1. Fetch all relevant volume CRs without worrying about openebsNS
@@ -109,26 +257,19 @@ func DescribeJivaVolume(c *client.K8sClient, vol *corev1.PersistentVolume) error
 		_, _ = fmt.Fprintf(os.Stderr, "failed to get JivaVolume for %s\n", vol.Name)
 		return err
 	}
-	// 2. Fill in JivaVolume related details
-	jivaVolInfo := util.VolumeInfo{
-		AccessMode:   util.AccessModeToString(vol.Spec.AccessModes),
-		Capacity:     util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
-		CSIDriver:    vol.Spec.CSI.Driver,
-		Name:         jv.Name,
-		Namespace:    jv.Namespace,
-		PVC:          vol.Spec.ClaimRef.Name,
-		ReplicaCount: jv.Spec.Policy.Target.ReplicationFactor,
-		VolumePhase:  vol.Status.Phase,
-		StorageClass: vol.Spec.StorageClassName,
-		Version:      jv.VersionDetails.Status.Current,
-		Size:         util.ConvertToIBytes(vol.Spec.Capacity.Storage().String()),
-		Status:       jv.Status.Status,
-		JVP:          jv.Annotations["openebs.io/volume-policy"],
+	// 2. Pick the schema adapter that can safely read this JivaVolume's
+	// fields, and bail out with a clear banner rather than nil-panicking
+	// or silently rendering blanks if none of them recognize its shape.
+	adapter := detectJivaSchemaAdapter(jv)
+	if adapter == nil {
+		fmt.Printf(unsupportedVersionBanner, jv.VersionDetails.Status.Current)
+		return nil
 	}
+	jivaVolInfo, portal := adapter.extract(jv, vol)
 	// 3. Print the Volume information
 	_ = util.PrintByTemplate("jivaVolumeInfo", JivaVolInfoTemplate, jivaVolInfo)
 	// 4. Print the Portal Information
-	util.TemplatePrinter(JivaPortalTemplate, jv)
+	util.TemplatePrinter(JivaPortalTemplate, portal)
 
 	replicaPodIPAndModeMap := make(map[string]string)
 	// Create Replica IP to Mode Map
@@ -189,5 +330,20 @@ func DescribeJivaVolume(c *client.K8sClient, vol *corev1.PersistentVolume) error
 	fmt.Println("Replica Data Volume Details :")
 	fmt.Println("-----------------------------")
 	util.TablePrinter(util.JivaReplicaPVCColumnDefinations, rows, printers.PrintOptions{Wide: true})
+	// 7. Print the PDB protecting the target/replica pods, if any
+	selector := "openebs.io/persistent-volume=" + jv.Name
+	if err := DescribePDB(c, jv.Namespace, selector, jv.Name); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed to describe PodDisruptionBudget for %s\n", vol.Name)
+	}
+	// 8. Print the snapshots taken of this volume, if any
+	snapRows, err := snapshot.GetSnapshots(c, vol.Spec.ClaimRef.Namespace, vol.Spec.ClaimRef.Name)
+	fmt.Println()
+	fmt.Println("Snapshots :")
+	fmt.Println("-----------")
+	if err != nil || len(snapRows) == 0 {
+		fmt.Println("No snapshots found for " + vol.Name)
+		return nil
+	}
+	util.TablePrinter(util.SnapshotColumnDefinations, snapRows, printers.PrintOptions{Wide: true})
 	return nil
 }
@@ -0,0 +1,142 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"testing"
+
+	"github.com/openebs/api/v2/pkg/apis/openebs.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func currentSchemaJV() v1alpha1.JivaVolume {
+	jv := v1alpha1.JivaVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pvc-current", Namespace: "openebs",
+			Labels:      map[string]string{"nodeID": "node1"},
+			Annotations: map[string]string{"openebs.io/volume-policy": "default-policy"},
+		},
+	}
+	jv.Spec.Policy.Target.ReplicationFactor = 3
+	jv.Spec.ISCSISpec.IQN = "iqn.2021-01.io.openebs:pvc-current"
+	jv.Spec.ISCSISpec.TargetIP = "10.0.0.1"
+	jv.Spec.ISCSISpec.TargetPort = "3260"
+	jv.VersionDetails.Status.Current = "3.3.0"
+	jv.Status.Status = "RW"
+	return jv
+}
+
+func legacySchemaJV() v1alpha1.JivaVolume {
+	return v1alpha1.JivaVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pvc-legacy", Namespace: "openebs",
+			Labels: map[string]string{
+				"nodeID":             "node1",
+				"replicas":           "2",
+				"openebs.io/version": "1.9.0",
+			},
+			Annotations: map[string]string{
+				"openebs.io/volume-policy": "",
+				"openebs.io/iqn":           "iqn.2019-01.io.openebs:pvc-legacy",
+				"openebs.io/targetportal":  "10.0.0.2:3260",
+			},
+		},
+		Status: v1alpha1.JivaVolumeStatus{Status: "RW"},
+	}
+}
+
+func unknownSchemaJV() v1alpha1.JivaVolume {
+	return v1alpha1.JivaVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-unknown", Namespace: "openebs"},
+	}
+}
+
+// versionOverridesFieldsJV has a populated Spec.Policy, which the
+// heuristic alone would hand to currentSchemaAdapter, but is labelled as a
+// 1.x JivaVolume, which must win.
+func versionOverridesFieldsJV() v1alpha1.JivaVolume {
+	jv := v1alpha1.JivaVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pvc-mislabeled", Namespace: "openebs",
+			Labels: map[string]string{"openebs.io/version": "1.9.0"},
+		},
+	}
+	jv.Spec.Policy.Target.ReplicationFactor = 3
+	return jv
+}
+
+func fakeJivaPV(name string) *corev1.PersistentVolume {
+	sc := "openebs-jiva-csi-default"
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: sc,
+			ClaimRef:         &corev1.ObjectReference{Name: name},
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "jiva.csi.openebs.io"},
+			},
+		},
+	}
+}
+
+func Test_detectJivaSchemaAdapter(t *testing.T) {
+	tests := []struct {
+		name     string
+		jv       v1alpha1.JivaVolume
+		wantName string
+	}{
+		{"current schema JivaVolume is handled by the current adapter", currentSchemaJV(), "current"},
+		{"legacy 1.x JivaVolume is handled by the legacy adapter", legacySchemaJV(), "legacy"},
+		{"unrecognizable JivaVolume has no adapter", unknownSchemaJV(), ""},
+		{"version string wins over populated fields", versionOverridesFieldsJV(), "legacy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := detectJivaSchemaAdapter(&tt.jv)
+			if tt.wantName == "" {
+				assert.Nil(t, adapter)
+				return
+			}
+			assert.Equal(t, tt.wantName, adapter.name())
+		})
+	}
+}
+
+func Test_currentSchemaAdapter_extract(t *testing.T) {
+	jv := currentSchemaJV()
+	vol := fakeJivaPV(jv.Name)
+	info, portal := currentSchemaAdapter{}.extract(&jv, vol)
+	assert.EqualValues(t, 3, info.ReplicaCount)
+	assert.Equal(t, "3.3.0", info.Version)
+	assert.Equal(t, "iqn.2021-01.io.openebs:pvc-current", portal.IQN)
+	assert.Equal(t, "10.0.0.1:3260", portal.Portal)
+}
+
+func Test_legacySchemaAdapter_extract(t *testing.T) {
+	jv := legacySchemaJV()
+	vol := fakeJivaPV(jv.Name)
+	info, portal := legacySchemaAdapter{}.extract(&jv, vol)
+	assert.EqualValues(t, 2, info.ReplicaCount)
+	assert.Equal(t, "1.9.0", info.Version)
+	assert.Equal(t, "iqn.2019-01.io.openebs:pvc-legacy", portal.IQN)
+	assert.Equal(t, "10.0.0.2:3260", portal.Portal)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020-2022 The OpenEBS Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openebs/openebsctl/pkg/client"
+	"github.com/openebs/openebsctl/pkg/util"
+)
+
+// PDBInfoTemplate prints the quorum-protection summary for the
+// PodDisruptionBudget guarding a volume's target/replica pods.
+const PDBInfoTemplate = `
+PodDisruptionBudget Details :
+-----------------------------
+NAME                 : {{.Name}}
+OWNER                : {{.Owner}}
+ALLOWED DISRUPTIONS  : {{.AllowedDisruptions}}
+CURRENT HEALTHY      : {{.CurrentHealthy}}
+DESIRED HEALTHY      : {{.DesiredHealthy}}
+
+`
+
+// pdbInfo is the normalized view of a PodDisruptionBudget printed via
+// PDBInfoTemplate.
+type pdbInfo struct {
+	Name               string
+	Owner              string
+	AllowedDisruptions int32
+	CurrentHealthy     int32
+	DesiredHealthy     int32
+}
+
+// DescribePDB lists the PodDisruptionBudgets in namespace ns whose own
+// labels match selector and prints the quorum status of the first one,
+// attributing it to owner (e.g. a CSPC or CV name). If no PDB is found, it
+// prints a short notice instead of failing the whole describe.
+func DescribePDB(c *client.K8sClient, ns, selector, owner string) error {
+	pdbs, err := c.K8sCS.PolicyV1().PodDisruptionBudgets(ns).List(util.Context(), metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil || len(pdbs.Items) == 0 {
+		fmt.Println("No PodDisruptionBudget found for " + owner)
+		return nil
+	}
+	pdb := pdbs.Items[0]
+	info := pdbInfo{
+		Name:               pdb.Name,
+		Owner:              owner,
+		AllowedDisruptions: pdb.Status.DisruptionsAllowed,
+		CurrentHealthy:     pdb.Status.CurrentHealthy,
+		DesiredHealthy:     pdb.Status.DesiredHealthy,
+	}
+	return util.PrintByTemplate("pdbInfo", PDBInfoTemplate, info)
+}